@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Fabi019/QuadTreeImageCompression/quadtree"
+)
+
+var inputFile string
+var outputFile string
+var outputFormat string
+var threshold uint
+var animFile string
+var animDelay uint
+var jpegQuality uint
+var gifColors uint
+var gifQuantizerName string
+var gifDrawerName string
+var pngLevelName string
+var errorMetric string
+var jobs uint
+
+func main() {
+	flag.StringVar(&inputFile, "i", "", "Input file. Supported types: png, jpg, gif, bmp")
+	flag.StringVar(&outputFile, "o", "output", "Output file name")
+	flag.StringVar(&outputFormat, "f", "", "Format for the output file (png, jpg, gif, bmp). Defaults to using the same as input file")
+	flag.UintVar(&threshold, "t", 10, "Quality threshold")
+	flag.StringVar(&animFile, "anim", "", "If set, also write an animated GIF of the subdivision process to this path")
+	flag.UintVar(&animDelay, "anim-delay", 50, "Delay between animation frames in hundredths of a second")
+	flag.UintVar(&jpegQuality, "quality", uint(jpeg.DefaultQuality), "JPEG output quality (1-100)")
+	flag.UintVar(&gifColors, "colors", 256, "GIF palette size (1-256)")
+	flag.StringVar(&gifQuantizerName, "quantizer", "", "GIF palette quantizer (median, mean, popularity). Defaults to the standard library's own quantizer")
+	flag.StringVar(&gifDrawerName, "drawer", "", "GIF color drawer (floyd, src). Defaults to the standard library's own drawer")
+	flag.StringVar(&pngLevelName, "png-level", "default", "PNG compression level (default, none, speed, best)")
+	flag.StringVar(&errorMetric, "metric", "rgb", "Region error metric used to decide subdivision (rgb, ycbcr, luma)")
+	flag.UintVar(&jobs, "jobs", 0, "Number of worker goroutines to process regions with. Defaults to the number of CPUs; use 1 for deterministic, single-threaded processing")
+
+	// Customize usage text
+	flag.Usage = func() {
+		fmt.Println("Usage for QuadTreeImageCompression 1.0.0: ")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Validate input file parameter
+	if inputFile == "" {
+		fmt.Println("Missing input file parameter!")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Validate output format parameter
+	if outputFormat != "png" && outputFormat != "jpg" && outputFormat != "gif" && outputFormat != "bmp" && outputFormat != "" {
+		fmt.Println("Invalid output file format!", outputFormat)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Validate encoder option parameters
+	if jpegQuality < 1 || jpegQuality > 100 {
+		fmt.Println("Invalid JPEG quality!", jpegQuality)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if gifColors < 1 || gifColors > 256 {
+		fmt.Println("Invalid GIF color count!", gifColors)
+		flag.Usage()
+		os.Exit(1)
+	}
+	gifQuantizer, err := quadtree.Quantizer(gifQuantizerName)
+	if err != nil {
+		fmt.Println(err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	gifDrawer, err := quadtree.Drawer(gifDrawerName)
+	if err != nil {
+		fmt.Println(err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	pngLevel, err := quadtree.PNGCompressionLevel(pngLevelName)
+	if err != nil {
+		fmt.Println(err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	metric, err := quadtree.ParseMetric(errorMetric)
+	if err != nil {
+		fmt.Println(err)
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	input, format, err := quadtree.LoadImage(quadtree.LocalFS, inputFile)
+	if err != nil {
+		log.Fatal("Error opening input file!\n", err)
+	}
+
+	log.Println("Image type:", format)
+	log.Println("Resolution:", input.Bounds().Size())
+	log.Println("Threshold:", threshold)
+
+	opts := quadtree.Options{
+		Threshold:   int(threshold),
+		Metric:      metric,
+		Parallelism: int(jobs),
+	}
+
+	var recorder *quadtree.AnimationRecorder
+	if animFile != "" {
+		recorder = &quadtree.AnimationRecorder{Delay: int(animDelay)}
+		opts.OnLevel = recorder.OnLevel
+	}
+
+	start := time.Now()
+
+	// Start of the compression
+	output := quadtree.Compress(input, opts)
+
+	log.Println("Compression done! Took:", time.Since(start))
+
+	if recorder != nil {
+		log.Println("Saving animation into", animFile, "...")
+		if err := recorder.Save(quadtree.LocalFS, animFile); err != nil {
+			log.Fatal("Error encoding animation file!\n", err)
+		}
+	}
+
+	if outputFormat == "" {
+		outputFormat = format
+	}
+
+	outFile := fmt.Sprintf("%s.%s", outputFile, outputFormat)
+	log.Println("Saving into", outFile, "...")
+	err = quadtree.SaveImage(quadtree.LocalFS, outFile, output,
+		quadtree.WithJPEGQuality(int(jpegQuality)),
+		quadtree.WithGIFColors(int(gifColors)),
+		quadtree.WithGIFQuantizer(gifQuantizer),
+		quadtree.WithGIFDrawer(gifDrawer),
+		quadtree.WithPNGCompressionLevel(pngLevel),
+	)
+	if err != nil {
+		log.Fatal("Error encoding output file!\n", err)
+	}
+}
@@ -0,0 +1,175 @@
+package quadtree
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/bmp"
+)
+
+// encodeOptions holds the settings SaveImage passes to the format-specific
+// encoder. Use the With* functions to override the defaults.
+type encodeOptions struct {
+	jpegQuality  int
+	gifColors    int
+	gifQuantizer draw.Quantizer
+	gifDrawer    draw.Drawer
+	pngLevel     png.CompressionLevel
+}
+
+func defaultEncodeOptions() encodeOptions {
+	return encodeOptions{
+		jpegQuality: jpeg.DefaultQuality,
+		gifColors:   256,
+		pngLevel:    png.DefaultCompression,
+	}
+}
+
+// EncodeOption configures SaveImage's output encoder.
+type EncodeOption func(*encodeOptions)
+
+// WithJPEGQuality sets the JPEG quality (1-100). Only used when saving to a
+// .jpg/.jpeg path.
+func WithJPEGQuality(quality int) EncodeOption {
+	return func(o *encodeOptions) { o.jpegQuality = quality }
+}
+
+// WithGIFColors sets the GIF palette size (1-256). Only used when saving to
+// a .gif path.
+func WithGIFColors(colors int) EncodeOption {
+	return func(o *encodeOptions) { o.gifColors = colors }
+}
+
+// WithGIFQuantizer sets the draw.Quantizer used to build the GIF palette. A
+// nil quantizer (the default) lets image/gif pick its own.
+func WithGIFQuantizer(q draw.Quantizer) EncodeOption {
+	return func(o *encodeOptions) { o.gifQuantizer = q }
+}
+
+// WithGIFDrawer sets the draw.Drawer used to map pixels onto the GIF
+// palette, e.g. draw.FloydSteinberg for dithering. A nil drawer (the
+// default) lets image/gif pick its own.
+func WithGIFDrawer(d draw.Drawer) EncodeOption {
+	return func(o *encodeOptions) { o.gifDrawer = d }
+}
+
+// WithPNGCompressionLevel sets the zlib compression effort used for PNG
+// output.
+func WithPNGCompressionLevel(level png.CompressionLevel) EncodeOption {
+	return func(o *encodeOptions) { o.pngLevel = level }
+}
+
+// LoadImage opens path through fs and decodes it, returning the image and
+// its format name (as reported by image.Decode).
+func LoadImage(fs FileSystem, path string) (image.Image, string, error) {
+	file, err := fs.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	return image.Decode(file)
+}
+
+// SaveImage encodes img and writes it to path through fs, picking the
+// format from the path's extension (.png, .jpg/.jpeg, .gif, .bmp). Pass
+// EncodeOptions to override the format-specific defaults.
+func SaveImage(fs FileSystem, path string, img image.Image, opts ...EncodeOption) error {
+	options := defaultEncodeOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	file, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		encoder := png.Encoder{CompressionLevel: options.pngLevel}
+		return encoder.Encode(file, img)
+	case ".gif":
+		return gif.Encode(file, img, &gif.Options{
+			NumColors: options.gifColors,
+			Quantizer: options.gifQuantizer,
+			Drawer:    options.gifDrawer,
+		})
+	case ".jpg", ".jpeg":
+		return jpeg.Encode(file, img, &jpeg.Options{Quality: options.jpegQuality})
+	case ".bmp":
+		return bmp.Encode(file, img)
+	default:
+		return fmt.Errorf("unsupported output format %q", filepath.Ext(path))
+	}
+}
+
+// SaveAnimation writes anim as a looping animated GIF to path through fs.
+func SaveAnimation(fs FileSystem, path string, anim *gif.GIF) error {
+	file, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	anim.LoopCount = 0
+	return gif.EncodeAll(file, anim)
+}
+
+// Quantizer resolves the -quantizer flag value (median, mean, popularity)
+// into a draw.Quantizer for WithGIFQuantizer. An empty name returns a nil
+// Quantizer, letting image/gif pick its own.
+func Quantizer(name string) (draw.Quantizer, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "median":
+		return bucketSplitQuantizer{byMedian: true}, nil
+	case "mean":
+		return bucketSplitQuantizer{byMedian: false}, nil
+	case "popularity":
+		return popularityQuantizer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown quantizer %q", name)
+	}
+}
+
+// Drawer resolves the -drawer flag value (floyd, src) into a draw.Drawer
+// for WithGIFDrawer. An empty name returns a nil Drawer, letting image/gif
+// pick its own.
+func Drawer(name string) (draw.Drawer, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "floyd":
+		return draw.FloydSteinberg, nil
+	case "src":
+		return draw.Src, nil
+	default:
+		return nil, fmt.Errorf("unknown drawer %q", name)
+	}
+}
+
+// PNGCompressionLevel resolves the -png-level flag value (default, none,
+// speed, best) into a png.CompressionLevel for WithPNGCompressionLevel.
+func PNGCompressionLevel(name string) (png.CompressionLevel, error) {
+	switch name {
+	case "", "default":
+		return png.DefaultCompression, nil
+	case "none":
+		return png.NoCompression, nil
+	case "speed":
+		return png.BestSpeed, nil
+	case "best":
+		return png.BestCompression, nil
+	default:
+		return 0, fmt.Errorf("unknown png compression level %q", name)
+	}
+}
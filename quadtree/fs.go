@@ -0,0 +1,29 @@
+package quadtree
+
+import (
+	"io"
+	"os"
+)
+
+// FileSystem abstracts the file access LoadImage, SaveImage and SaveAnimation
+// need, so callers such as an
+// HTTP handler can decode from a multipart.File or encode to an
+// http.ResponseWriter without the package touching the OS filesystem
+// directly.
+type FileSystem interface {
+	Open(name string) (io.ReadCloser, error)
+	Create(name string) (io.WriteCloser, error)
+}
+
+// LocalFS is the default FileSystem, backed by the OS filesystem.
+var LocalFS FileSystem = localFS{}
+
+type localFS struct{}
+
+func (localFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (localFS) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
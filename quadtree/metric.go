@@ -0,0 +1,161 @@
+package quadtree
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Metric selects how a region's error against its average color is
+// computed, which in turn drives the subdivision decision.
+type Metric int
+
+const (
+	// MetricRGB sums the absolute RGB differences against the average
+	// color, weighting every channel equally.
+	MetricRGB Metric = iota
+	// MetricYCbCr converts to YCbCr and weights luminance differences far
+	// more heavily than chroma, matching how humans perceive images.
+	MetricYCbCr
+	// MetricLuma is the luma-only variant of MetricYCbCr: it ignores
+	// chroma entirely.
+	MetricLuma
+)
+
+// ParseMetric parses the -metric flag value (rgb, ycbcr, luma).
+func ParseMetric(name string) (Metric, error) {
+	switch name {
+	case "", "rgb":
+		return MetricRGB, nil
+	case "ycbcr":
+		return MetricYCbCr, nil
+	case "luma":
+		return MetricLuma, nil
+	default:
+		return 0, fmt.Errorf("unknown metric %q", name)
+	}
+}
+
+// Weights for MetricYCbCr. Luminance is weighted far more heavily than
+// chroma since the human eye is much more sensitive to brightness variation
+// than color variation, which is also what lets JPEG get away with
+// subsampling chroma.
+const (
+	lumaWeight   = 4
+	chromaWeight = 1
+)
+
+func colorAverage(image *image.NRGBA) color.NRGBA {
+	size := image.Bounds()
+	minX, minY := size.Min.X, size.Min.Y
+	maxX, maxY := size.Max.X, size.Max.Y
+
+	n := size.Dx() * size.Dy()
+	sum := make([]uint32, 3)
+
+	for y := minY; y < maxY; y++ {
+		offset := image.PixOffset(minX, y)
+		for x := minX; x < maxX; x++ {
+			sum[0] += uint32(image.Pix[offset+0])
+			sum[1] += uint32(image.Pix[offset+1])
+			sum[2] += uint32(image.Pix[offset+2])
+			offset += 4
+		}
+	}
+
+	avgR := uint8(sum[0] / uint32(n))
+	avgG := uint8(sum[1] / uint32(n))
+	avgB := uint8(sum[2] / uint32(n))
+	return color.NRGBA{R: avgR, G: avgG, B: avgB, A: 255}
+}
+
+// regionError dispatches to the error calculation selected by metric.
+func regionError(img *image.NRGBA, avg color.NRGBA, metric Metric) int {
+	switch metric {
+	case MetricYCbCr:
+		avgY, avgCb, avgCr := color.RGBToYCbCr(avg.R, avg.G, avg.B)
+		return regionErrorYCbCr(img, avgY, avgCb, avgCr)
+	case MetricLuma:
+		avgY, _, _ := color.RGBToYCbCr(avg.R, avg.G, avg.B)
+		return regionErrorLuma(img, avgY)
+	default:
+		return regionErrorRGB(img, avg)
+	}
+}
+
+func regionErrorRGB(image *image.NRGBA, averageColor color.NRGBA) int {
+	size := image.Bounds()
+	minX, minY := size.Min.X, size.Min.Y
+	maxX, maxY := size.Max.X, size.Max.Y
+
+	n := size.Dx() * size.Dy()
+	sum := make([]int, 3)
+
+	for y := minY; y < maxY; y++ {
+		offset := image.PixOffset(minX, y)
+		for x := minX; x < maxX; x++ {
+			sum[0] += absDiff(int(image.Pix[offset+0]), int(averageColor.R))
+			sum[1] += absDiff(int(image.Pix[offset+1]), int(averageColor.G))
+			sum[2] += absDiff(int(image.Pix[offset+2]), int(averageColor.B))
+			offset += 4
+		}
+	}
+
+	return (sum[0] + sum[1] + sum[2]) / (n * 3)
+}
+
+// regionErrorYCbCr converts every pixel and the region average to YCbCr and
+// sums a weighted error that favors luminance differences over chroma
+// differences.
+func regionErrorYCbCr(img *image.NRGBA, avgY, avgCb, avgCr uint8) int {
+	size := img.Bounds()
+	minX, minY := size.Min.X, size.Min.Y
+	maxX, maxY := size.Max.X, size.Max.Y
+
+	n := size.Dx() * size.Dy()
+	var sum int
+
+	for y := minY; y < maxY; y++ {
+		offset := img.PixOffset(minX, y)
+		for x := minX; x < maxX; x++ {
+			py, pcb, pcr := color.RGBToYCbCr(img.Pix[offset+0], img.Pix[offset+1], img.Pix[offset+2])
+			dy := absDiff(int(py), int(avgY))
+			dc := absDiff(int(pcb), int(avgCb)) + absDiff(int(pcr), int(avgCr))
+			sum += lumaWeight*dy + chromaWeight*dc
+			offset += 4
+		}
+	}
+
+	// n is always >= 1 for a valid region, so this can never divide by zero
+	// even on the degenerate 1xN strips produced near the leaves.
+	return sum / (n * (lumaWeight + 2*chromaWeight))
+}
+
+// regionErrorLuma is the luma-only variant of regionErrorYCbCr.
+func regionErrorLuma(img *image.NRGBA, avgY uint8) int {
+	size := img.Bounds()
+	minX, minY := size.Min.X, size.Min.Y
+	maxX, maxY := size.Max.X, size.Max.Y
+
+	n := size.Dx() * size.Dy()
+	var sum int
+
+	for y := minY; y < maxY; y++ {
+		offset := img.PixOffset(minX, y)
+		for x := minX; x < maxX; x++ {
+			py, _, _ := color.RGBToYCbCr(img.Pix[offset+0], img.Pix[offset+1], img.Pix[offset+2])
+			sum += absDiff(int(py), int(avgY))
+			offset += 4
+		}
+	}
+
+	return sum / n
+}
+
+func absDiff(a int, b int) int {
+	result := a - b
+	if result < 0 {
+		return -result
+	}
+	return result
+}
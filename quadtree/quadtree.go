@@ -0,0 +1,175 @@
+// Package quadtree implements the quadtree-based image compressor: an
+// image is recursively subdivided into four regions wherever a region's
+// color error exceeds a threshold, and each leaf region is flattened to its
+// average color.
+package quadtree
+
+import (
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"runtime"
+	"sync"
+)
+
+// Options configures a single Compress call.
+type Options struct {
+	// Threshold is the per-region error threshold above which a region is
+	// subdivided further.
+	Threshold int
+
+	// MinSize is the smallest region edge length that may still be
+	// subdivided. Defaults to 2 if zero.
+	MinSize int
+
+	// MaxDepth caps how many times a region may be subdivided. Zero means
+	// unlimited.
+	MaxDepth int
+
+	// Metric selects how a region's error against its average color is
+	// computed. Defaults to MetricRGB.
+	Metric Metric
+
+	// Parallelism bounds the number of worker goroutines processing
+	// regions of the same depth concurrently. Defaults to
+	// runtime.NumCPU() if zero; set to 1 for deterministic
+	// single-threaded processing.
+	Parallelism int
+
+	// OnLevel, if set, is called once per subdivision depth, after every
+	// region at that depth has finished processing and before the next
+	// depth starts, with the current state of the output image - e.g. to
+	// record an animation frame. Since it runs between depths rather than
+	// concurrently with them, it is safe to read output without
+	// additional synchronization. The image must not be retained or
+	// mutated by the callback.
+	OnLevel func(depth int, output *image.NRGBA)
+}
+
+// Compress copies img into a fresh NRGBA buffer and subdivides it breadth
+// first: every region at a given depth is resolved, by a fixed-size pool of
+// opts.Parallelism workers, before any of their children are considered at
+// the next depth. This bounds concurrent worker goroutines to opts.Parallelism
+// regardless of image size or threshold, and lets opts.OnLevel observe a
+// clean, fully-settled frame once per depth instead of racing with
+// in-flight subdivision.
+func Compress(img image.Image, opts Options) *image.NRGBA {
+	minSize := opts.MinSize
+	if minSize == 0 {
+		minSize = 2
+	}
+	jobs := opts.Parallelism
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	output := image.NewNRGBA(img.Bounds())
+	draw.Draw(output, img.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	queue := []image.Rectangle{output.Bounds()}
+
+	for depth := 0; len(queue) > 0; depth++ {
+		atMaxDepth := opts.MaxDepth > 0 && depth >= opts.MaxDepth
+		queue = processLevel(output, queue, opts.Threshold, opts.Metric, minSize, atMaxDepth, jobs)
+
+		if opts.OnLevel != nil {
+			opts.OnLevel(depth, output)
+		}
+	}
+
+	return output
+}
+
+// processLevel runs regions through a pool of up to jobs workers and
+// returns the child regions collected for the next depth, if any. It
+// blocks until every region has been processed, so the caller can safely
+// read output the moment it returns.
+func processLevel(output *image.NRGBA, regions []image.Rectangle, threshold int, metric Metric, minSize int, atMaxDepth bool, jobs int) []image.Rectangle {
+	if jobs > len(regions) {
+		jobs = len(regions)
+	}
+
+	work := make(chan image.Rectangle)
+	go func() {
+		defer close(work)
+		for _, region := range regions {
+			work <- region
+		}
+	}()
+
+	var mu sync.Mutex
+	var next []image.Rectangle
+	var wg sync.WaitGroup
+
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for region := range work {
+				children := processRegion(output, region, threshold, metric, minSize, atMaxDepth)
+				if children != nil {
+					mu.Lock()
+					next = append(next, children...)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return next
+}
+
+// processRegion computes the average color and error for region and either
+// fills it (returning nil) or splits it into four child regions to be
+// processed at the next depth.
+func processRegion(output *image.NRGBA, region image.Rectangle, threshold int, metric Metric, minSize int, atMaxDepth bool) []image.Rectangle {
+	width, height := region.Dx(), region.Dy()
+	regionImg := output.SubImage(region).(*image.NRGBA)
+
+	avg := colorAverage(regionImg)
+	errorVal := regionError(regionImg, avg, metric)
+
+	if !atMaxDepth && errorVal > threshold && width >= minSize && height >= minSize {
+		return []image.Rectangle{
+			// Top left
+			image.Rect(0, 0, width/2, height/2).Add(region.Min),
+			// Top right
+			image.Rect(width/2, 0, width, height/2).Add(region.Min),
+			// Bottom left
+			image.Rect(0, height/2, width/2, height).Add(region.Min),
+			// Bottom right
+			image.Rect(width/2, height/2, width, height).Add(region.Min),
+		}
+	}
+
+	draw.Draw(output, region, &image.Uniform{C: avg}, image.Point{}, draw.Src)
+	return nil
+}
+
+// AnimationRecorder collects one frame per subdivision level via
+// Options.OnLevel and saves the result as an animated GIF with Save.
+type AnimationRecorder struct {
+	// Delay between frames, in hundredths of a second.
+	Delay int
+
+	anim gif.GIF
+}
+
+// OnLevel is an Options.OnLevel callback that snapshots output as the next
+// frame of the recording.
+func (r *AnimationRecorder) OnLevel(_ int, output *image.NRGBA) {
+	frame := image.NewPaletted(output.Bounds(), palette.Plan9)
+	draw.Draw(frame, output.Bounds(), output, output.Bounds().Min, draw.Src)
+
+	r.anim.Image = append(r.anim.Image, frame)
+	r.anim.Delay = append(r.anim.Delay, r.Delay)
+}
+
+// Save writes the recorded frames as a looping animated GIF to path
+// through fs.
+func (r *AnimationRecorder) Save(fs FileSystem, path string) error {
+	r.anim.LoopCount = 0
+	return SaveAnimation(fs, path, &r.anim)
+}
@@ -0,0 +1,167 @@
+package quadtree
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// colorBucket is a set of colors sampled from an image, used as the unit of
+// work for the bucket-splitting quantizers below.
+type colorBucket []color.NRGBA
+
+func collectColors(m image.Image) colorBucket {
+	bounds := m.Bounds()
+	colors := make(colorBucket, 0, bounds.Dx()*bounds.Dy())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := m.At(x, y).RGBA()
+			colors = append(colors, color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)})
+		}
+	}
+
+	return colors
+}
+
+func channelValue(c color.NRGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+func (b colorBucket) channelRange(channel int) (min, max uint8) {
+	min, max = 255, 0
+	for _, c := range b {
+		v := channelValue(c, channel)
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+func (b colorBucket) channelMean(channel int) uint8 {
+	var sum uint32
+	for _, c := range b {
+		sum += uint32(channelValue(c, channel))
+	}
+	return uint8(sum / uint32(len(b)))
+}
+
+func (b colorBucket) averageColor() color.NRGBA {
+	var sumR, sumG, sumB, sumA uint32
+	for _, c := range b {
+		sumR += uint32(c.R)
+		sumG += uint32(c.G)
+		sumB += uint32(c.B)
+		sumA += uint32(c.A)
+	}
+	n := uint32(len(b))
+	return color.NRGBA{R: uint8(sumR / n), G: uint8(sumG / n), B: uint8(sumB / n), A: uint8(sumA / n)}
+}
+
+// bucketSplitQuantizer repeatedly splits the bucket with the widest channel
+// range into two, either at the median (equal-population buckets) or at the
+// mean (equal-distance buckets), until there are enough buckets to fill the
+// requested palette size. It implements draw.Quantizer.
+type bucketSplitQuantizer struct {
+	// byMedian selects median-cut splitting; otherwise splitting is done at
+	// the channel mean.
+	byMedian bool
+}
+
+func (q bucketSplitQuantizer) Quantize(p color.Palette, m image.Image) color.Palette {
+	size := cap(p)
+	if size == 0 {
+		size = 256
+	}
+
+	buckets := []colorBucket{collectColors(m)}
+
+	for len(buckets) < size {
+		splitIdx, channel, widest := -1, 0, uint8(0)
+		for i, bucket := range buckets {
+			if len(bucket) < 2 {
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				lo, hi := bucket.channelRange(c)
+				if hi-lo > widest {
+					widest = hi - lo
+					splitIdx = i
+					channel = c
+				}
+			}
+		}
+		if splitIdx == -1 {
+			// No bucket has more than one distinct value left to split on.
+			break
+		}
+
+		bucket := buckets[splitIdx]
+		sort.Slice(bucket, func(i, j int) bool {
+			return channelValue(bucket[i], channel) < channelValue(bucket[j], channel)
+		})
+
+		mid := len(bucket) / 2
+		if !q.byMedian {
+			mean := bucket.channelMean(channel)
+			if i := sort.Search(len(bucket), func(i int) bool { return channelValue(bucket[i], channel) >= mean }); i > 0 && i < len(bucket) {
+				mid = i
+			}
+		}
+
+		buckets[splitIdx] = bucket[:mid:mid]
+		buckets = append(buckets, bucket[mid:])
+	}
+
+	for _, bucket := range buckets {
+		if len(bucket) > 0 {
+			p = append(p, bucket.averageColor())
+		}
+	}
+	return p
+}
+
+// popularityQuantizer builds a palette from the N most frequently occurring
+// colors in the image. It implements draw.Quantizer.
+type popularityQuantizer struct{}
+
+func (popularityQuantizer) Quantize(p color.Palette, m image.Image) color.Palette {
+	size := cap(p)
+	if size == 0 {
+		size = 256
+	}
+
+	counts := make(map[color.NRGBA]int)
+	for _, c := range collectColors(m) {
+		counts[c]++
+	}
+
+	type countedColor struct {
+		color.NRGBA
+		count int
+	}
+	ranked := make([]countedColor, 0, len(counts))
+	for c, n := range counts {
+		ranked = append(ranked, countedColor{c, n})
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].count > ranked[j].count })
+
+	if len(ranked) > size {
+		ranked = ranked[:size]
+	}
+	for _, c := range ranked {
+		p = append(p, c.NRGBA)
+	}
+	return p
+}